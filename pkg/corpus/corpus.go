@@ -0,0 +1,181 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package corpus
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"buzzer/pkg/crypto/envelope"
+)
+
+// manifestSuffix is appended to a bytecode hash to get its manifest's
+// storage key, keeping it next to (but distinguishable from) any raw
+// artifact a backend also stores under the same hash.
+const manifestSuffix = ".manifest.json"
+
+// encryptedManifestSuffix marks a manifest that was sealed with
+// envelope.Seal before being stored, as opposed to a plain JSON manifest.
+const encryptedManifestSuffix = ".manifest.jwe.json"
+
+// Backend is the storage a Corpus persists entries to. Implementations only
+// need to support byte-blob storage keyed by name, so local disk, an
+// S3-compatible bucket, or any io/fs-shaped remote store all satisfy it.
+type Backend interface {
+	// Put stores data under name, overwriting any existing object.
+	Put(ctx context.Context, name string, data []byte) error
+	// Get retrieves the object stored under name.
+	Get(ctx context.Context, name string) ([]byte, error)
+	// List returns the names of all objects stored under the given prefix.
+	List(ctx context.Context, prefix string) ([]string, error)
+}
+
+// Corpus persists interesting inputs and crash PoCs to a Backend.
+type Corpus struct {
+	backend Backend
+
+	// encryptFor, when non-empty, makes Save seal every manifest with
+	// envelope.Seal for these recipients instead of storing it in the
+	// clear; crash PoCs are effectively 0-days and shouldn't sit in
+	// plaintext in shared object storage.
+	encryptFor []envelope.Recipient
+	// decryptWith lets Reseed open manifests that were sealed above; it's
+	// only needed by operators who both own a private key and want to
+	// reseed strategies from the corpus, not by every writer.
+	decryptWith []envelope.KeyUnwrapper
+}
+
+// Option configures optional Corpus behavior.
+type Option func(*Corpus)
+
+// WithEncryption makes every manifest Save stores be sealed for recipients
+// via a JWE-style envelope instead of stored in the clear.
+func WithEncryption(recipients []envelope.Recipient) Option {
+	return func(c *Corpus) { c.encryptFor = recipients }
+}
+
+// WithDecryption lets Reseed open manifests previously sealed with
+// WithEncryption, using the given private keys.
+func WithDecryption(unwrappers []envelope.KeyUnwrapper) Option {
+	return func(c *Corpus) { c.decryptWith = unwrappers }
+}
+
+// New returns a Corpus that persists to backend.
+func New(backend Backend, opts ...Option) *Corpus {
+	c := &Corpus{backend: backend}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Save stores bytecode (and, for reproducibility, the C PoC that generates
+// it) under a manifest describing why it was kept. It's meant to be called
+// by a strategy whenever a generated program increases coverage or crashes.
+// If the Corpus was built with WithEncryption, the manifest is sealed before
+// being written.
+func (c *Corpus) Save(ctx context.Context, strategyName string, bytecode []uint64, poc []string, coverageDelta int, verdict Verdict, kernelVersion string) (*Manifest, error) {
+	hash := hashBytecode(bytecode)
+	m := &Manifest{
+		BytecodeHash:  hash,
+		Strategy:      strategyName,
+		Verdict:       verdict,
+		CoverageDelta: coverageDelta,
+		Timestamp:     time.Now(),
+		KernelVersion: kernelVersion,
+		Bytecode:      bytecode,
+		Poc:           poc,
+	}
+
+	data, err := m.Marshal()
+	if err != nil {
+		return nil, fmt.Errorf("corpus: marshaling manifest: %v", err)
+	}
+
+	name := hash + manifestSuffix
+	if len(c.encryptFor) > 0 {
+		env, err := envelope.Seal(data, c.encryptFor...)
+		if err != nil {
+			return nil, fmt.Errorf("corpus: sealing manifest %s: %v", hash, err)
+		}
+		if data, err = env.Marshal(); err != nil {
+			return nil, fmt.Errorf("corpus: marshaling sealed manifest %s: %v", hash, err)
+		}
+		name = hash + encryptedManifestSuffix
+	}
+
+	if err := c.backend.Put(ctx, name, data); err != nil {
+		return nil, fmt.Errorf("corpus: storing manifest %s: %v", hash, err)
+	}
+	return m, nil
+}
+
+// Reseed lists every manifest in the backend and returns them, so a
+// ControlUnit can hand them back to strategies that know how to start from
+// previously interesting programs. Encrypted manifests are skipped unless
+// the Corpus was built with WithDecryption.
+func (c *Corpus) Reseed(ctx context.Context) ([]*Manifest, error) {
+	names, err := c.backend.List(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("corpus: listing entries: %v", err)
+	}
+
+	var manifests []*Manifest
+	for _, name := range names {
+		data, err := c.backend.Get(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("corpus: reading %s: %v", name, err)
+		}
+
+		if strings.HasSuffix(name, encryptedManifestSuffix) {
+			if len(c.decryptWith) == 0 {
+				continue
+			}
+			env, err := envelope.UnmarshalEnvelope(data)
+			if err != nil {
+				return nil, fmt.Errorf("corpus: parsing sealed %s: %v", name, err)
+			}
+			if data, err = envelope.Open(env, c.decryptWith...); err != nil {
+				return nil, fmt.Errorf("corpus: opening sealed %s: %v", name, err)
+			}
+		} else if !strings.HasSuffix(name, manifestSuffix) {
+			continue
+		}
+
+		m, err := UnmarshalManifest(data)
+		if err != nil {
+			return nil, fmt.Errorf("corpus: parsing %s: %v", name, err)
+		}
+		manifests = append(manifests, m)
+	}
+	return manifests, nil
+}
+
+// hashBytecode returns the hex SHA-256 of the bytecode, used as the
+// content-addressed key every entry is stored under.
+func hashBytecode(bytecode []uint64) string {
+	h := sha256.New()
+	buf := make([]byte, 8)
+	for _, insn := range bytecode {
+		binary.LittleEndian.PutUint64(buf, insn)
+		h.Write(buf)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
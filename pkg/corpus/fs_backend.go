@@ -0,0 +1,63 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package corpus
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// FSBackend is a Backend that stores every entry as a file under root.
+type FSBackend struct {
+	root string
+}
+
+// NewFSBackend returns an FSBackend rooted at dir, creating it if needed.
+func NewFSBackend(dir string) (*FSBackend, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("corpus: creating %s: %v", dir, err)
+	}
+	return &FSBackend{root: dir}, nil
+}
+
+// Put implements Backend.
+func (b *FSBackend) Put(ctx context.Context, name string, data []byte) error {
+	return os.WriteFile(filepath.Join(b.root, name), data, 0o644)
+}
+
+// Get implements Backend.
+func (b *FSBackend) Get(ctx context.Context, name string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(b.root, name))
+}
+
+// List implements Backend.
+func (b *FSBackend) List(ctx context.Context, prefix string) ([]string, error) {
+	entries, err := os.ReadDir(b.root)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		if prefix == "" || len(e.Name()) >= len(prefix) && e.Name()[:len(prefix)] == prefix {
+			names = append(names, e.Name())
+		}
+	}
+	return names, nil
+}
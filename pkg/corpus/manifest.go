@@ -0,0 +1,69 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package corpus persists interesting programs and crash-triggering PoCs
+// discovered while fuzzing, so a run can be reseeded and crashes can be
+// reproduced outside of the fuzzer.
+package corpus
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Verdict records why an entry was kept.
+type Verdict string
+
+const (
+	// VerdictInteresting marks a program that increased coverage.
+	VerdictInteresting Verdict = "interesting"
+	// VerdictCrash marks a program that crashed the kernel or verifier.
+	VerdictCrash Verdict = "crash"
+)
+
+// Manifest describes a single stored entry: the bytecode that produced it,
+// which strategy generated it, and enough context to reproduce it outside
+// of buzzer.
+type Manifest struct {
+	// BytecodeHash identifies the entry; it's also used as its storage key.
+	BytecodeHash string `json:"bytecode_hash"`
+
+	Strategy      string    `json:"strategy"`
+	Verdict       Verdict   `json:"verdict"`
+	CoverageDelta int       `json:"coverage_delta"`
+	Timestamp     time.Time `json:"timestamp"`
+	KernelVersion string    `json:"kernel_version"`
+
+	// Bytecode is the raw eBPF program, as produced by
+	// ebpf.Instruction.GenerateBytecode.
+	Bytecode []uint64 `json:"bytecode"`
+
+	// Poc holds the C repro macros produced by ebpf.Instruction.GeneratePoc,
+	// one line per instruction.
+	Poc []string `json:"poc"`
+}
+
+// Marshal serializes the manifest to JSON.
+func (m *Manifest) Marshal() ([]byte, error) {
+	return json.MarshalIndent(m, "", "  ")
+}
+
+// UnmarshalManifest parses a manifest previously produced by Marshal.
+func UnmarshalManifest(data []byte) (*Manifest, error) {
+	m := &Manifest{}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
@@ -0,0 +1,71 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package corpus
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+)
+
+// ObjectAPI is the minimal surface ObjectBackend needs from an
+// object-storage client. Any S3-compatible SDK client (AWS S3, MinIO,
+// Ceph RGW, ...) can be adapted to it; a GCS or Azure Blob client would
+// implement the same three methods to plug into ObjectBackend unchanged.
+type ObjectAPI interface {
+	PutObject(ctx context.Context, bucket, key string, body io.Reader) error
+	GetObject(ctx context.Context, bucket, key string) (io.ReadCloser, error)
+	ListObjects(ctx context.Context, bucket, prefix string) ([]string, error)
+}
+
+// ObjectBackend is a Backend that stores every entry as an object in an
+// S3-compatible bucket.
+type ObjectBackend struct {
+	api    ObjectAPI
+	bucket string
+}
+
+// NewObjectBackend returns an ObjectBackend that stores into bucket via api.
+func NewObjectBackend(api ObjectAPI, bucket string) *ObjectBackend {
+	return &ObjectBackend{api: api, bucket: bucket}
+}
+
+// Put implements Backend.
+func (b *ObjectBackend) Put(ctx context.Context, name string, data []byte) error {
+	if err := b.api.PutObject(ctx, b.bucket, name, bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("corpus: put %s/%s: %v", b.bucket, name, err)
+	}
+	return nil
+}
+
+// Get implements Backend.
+func (b *ObjectBackend) Get(ctx context.Context, name string) ([]byte, error) {
+	rc, err := b.api.GetObject(ctx, b.bucket, name)
+	if err != nil {
+		return nil, fmt.Errorf("corpus: get %s/%s: %v", b.bucket, name, err)
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+// List implements Backend.
+func (b *ObjectBackend) List(ctx context.Context, prefix string) ([]string, error) {
+	names, err := b.api.ListObjects(ctx, b.bucket, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("corpus: list %s/%s*: %v", b.bucket, prefix, err)
+	}
+	return names, nil
+}
@@ -0,0 +1,114 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envelope
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"fmt"
+)
+
+// aesKWDefaultIV is the 64-bit default initial value from RFC 3394 section
+// 2.2.3.1.
+var aesKWDefaultIV = [8]byte{0xA6, 0xA6, 0xA6, 0xA6, 0xA6, 0xA6, 0xA6, 0xA6}
+
+// aesKeyWrap implements the RFC 3394 AES Key Wrap algorithm, used by
+// ECDH-ES+A256KW to wrap the content encryption key once a shared key
+// encryption key has been derived.
+func aesKeyWrap(kek cipher.Block, plaintext []byte) ([]byte, error) {
+	if len(plaintext)%8 != 0 || len(plaintext) < 16 {
+		return nil, fmt.Errorf("aeskw: plaintext length %d is not a multiple of 8 bytes (>= 16)", len(plaintext))
+	}
+	n := len(plaintext) / 8
+
+	// A is the running integrity/IV register; R holds the n 64-bit blocks.
+	var a [8]byte
+	copy(a[:], aesKWDefaultIV[:])
+	r := make([][8]byte, n)
+	for i := 0; i < n; i++ {
+		copy(r[i][:], plaintext[i*8:(i+1)*8])
+	}
+
+	buf := make([]byte, 16)
+	for j := 0; j <= 5; j++ {
+		for i := 1; i <= n; i++ {
+			copy(buf[:8], a[:])
+			copy(buf[8:], r[i-1][:])
+			block := make([]byte, 16)
+			kek.Encrypt(block, buf)
+
+			var t uint64 = uint64(n*j + i)
+			var tBytes [8]byte
+			binary.BigEndian.PutUint64(tBytes[:], t)
+			for k := range a {
+				a[k] = block[k] ^ tBytes[k]
+			}
+			copy(r[i-1][:], block[8:])
+		}
+	}
+
+	out := make([]byte, 8+len(plaintext))
+	copy(out[:8], a[:])
+	for i := 0; i < n; i++ {
+		copy(out[8+i*8:8+(i+1)*8], r[i][:])
+	}
+	return out, nil
+}
+
+// aesKeyUnwrap is the inverse of aesKeyWrap.
+func aesKeyUnwrap(kek cipher.Block, wrapped []byte) ([]byte, error) {
+	if len(wrapped)%8 != 0 || len(wrapped) < 24 {
+		return nil, fmt.Errorf("aeskw: wrapped length %d is invalid", len(wrapped))
+	}
+	n := len(wrapped)/8 - 1
+
+	var a [8]byte
+	copy(a[:], wrapped[:8])
+	r := make([][8]byte, n)
+	for i := 0; i < n; i++ {
+		copy(r[i][:], wrapped[8+i*8:8+(i+1)*8])
+	}
+
+	buf := make([]byte, 16)
+	for j := 5; j >= 0; j-- {
+		for i := n; i >= 1; i-- {
+			var t uint64 = uint64(n*j + i)
+			var tBytes [8]byte
+			binary.BigEndian.PutUint64(tBytes[:], t)
+			var aXorT [8]byte
+			for k := range a {
+				aXorT[k] = a[k] ^ tBytes[k]
+			}
+
+			copy(buf[:8], aXorT[:])
+			copy(buf[8:], r[i-1][:])
+			block := make([]byte, 16)
+			kek.Decrypt(block, buf)
+
+			copy(a[:], block[:8])
+			copy(r[i-1][:], block[8:])
+		}
+	}
+
+	if a != aesKWDefaultIV {
+		return nil, fmt.Errorf("aeskw: integrity check failed")
+	}
+
+	out := make([]byte, n*8)
+	for i := 0; i < n; i++ {
+		copy(out[i*8:(i+1)*8], r[i][:])
+	}
+	return out, nil
+}
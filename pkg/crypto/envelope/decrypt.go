@@ -0,0 +1,50 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envelope
+
+import (
+	"fmt"
+	"os"
+)
+
+// DecryptFile opens the sealed envelope at inPath with decryptionKeySpecs
+// (a --decryption-keys-style comma-separated list of `jwe:/path` entries)
+// and writes the recovered plaintext to outPath. It backs the `buzzer
+// decrypt` subcommand.
+func DecryptFile(inPath, outPath, decryptionKeySpecs string) error {
+	unwrappers, err := ParseUnwrapperSpecs(decryptionKeySpecs)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(inPath)
+	if err != nil {
+		return fmt.Errorf("envelope: reading %s: %v", inPath, err)
+	}
+	env, err := UnmarshalEnvelope(data)
+	if err != nil {
+		return fmt.Errorf("envelope: parsing %s: %v", inPath, err)
+	}
+
+	plaintext, err := Open(env, unwrappers...)
+	if err != nil {
+		return fmt.Errorf("envelope: decrypting %s: %v", inPath, err)
+	}
+
+	if err := os.WriteFile(outPath, plaintext, 0o600); err != nil {
+		return fmt.Errorf("envelope: writing %s: %v", outPath, err)
+	}
+	return nil
+}
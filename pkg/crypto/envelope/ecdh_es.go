@@ -0,0 +1,122 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envelope
+
+import (
+	"crypto/aes"
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+)
+
+// ecdhESA256KWAlg is the JWE "alg" this wrapper implements. Plain ECDH-ES
+// (direct key agreement) only supports a single recipient per JWE, which
+// doesn't fit buzzer's "any of several keys can decrypt" model, so this
+// package always uses the key-wrapping variant: the ECDH shared secret
+// derives a per-recipient key-encryption key that then RFC 3394 wraps the
+// CEK, same as every other recipient's entry.
+const ecdhESA256KWAlg = "ECDH-ES+A256KW"
+
+const ecdhESEphemeralPubHeader = "epk"
+
+// ECDHESP256Wrapper wraps/unwraps a content encryption key for a single
+// recipient's P-256 key pair.
+type ECDHESP256Wrapper struct {
+	Public  *ecdh.PublicKey
+	Private *ecdh.PrivateKey
+}
+
+// Algorithm implements KeyWrapper and KeyUnwrapper.
+func (w *ECDHESP256Wrapper) Algorithm() string { return ecdhESA256KWAlg }
+
+// WrapKey implements KeyWrapper. It generates a fresh ephemeral key pair,
+// derives a key-encryption key from the ECDH shared secret via
+// concatenation KDF (NIST SP 800-56A, single round since a 256-bit key
+// fits in one SHA-256 block), and uses it to RFC 3394 wrap cek.
+func (w *ECDHESP256Wrapper) WrapKey(cek []byte) ([]byte, map[string]string, error) {
+	curve := ecdh.P256()
+	ephemeral, err := curve.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ecdh-es: generating ephemeral key: %v", err)
+	}
+
+	shared, err := ephemeral.ECDH(w.Public)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ecdh-es: key agreement: %v", err)
+	}
+
+	kek := concatKDF(shared, ecdhESA256KWAlg, 32)
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, nil, err
+	}
+	wrapped, err := aesKeyWrap(block, cek)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	header := map[string]string{
+		ecdhESEphemeralPubHeader: base64.RawURLEncoding.EncodeToString(ephemeral.PublicKey().Bytes()),
+	}
+	return wrapped, header, nil
+}
+
+// UnwrapKey implements KeyUnwrapper.
+func (w *ECDHESP256Wrapper) UnwrapKey(wrapped []byte, header map[string]string) ([]byte, error) {
+	epkBytes, err := base64.RawURLEncoding.DecodeString(header[ecdhESEphemeralPubHeader])
+	if err != nil {
+		return nil, fmt.Errorf("ecdh-es: decoding ephemeral public key: %v", err)
+	}
+	curve := ecdh.P256()
+	epk, err := curve.NewPublicKey(epkBytes)
+	if err != nil {
+		return nil, fmt.Errorf("ecdh-es: parsing ephemeral public key: %v", err)
+	}
+
+	shared, err := w.Private.ECDH(epk)
+	if err != nil {
+		return nil, fmt.Errorf("ecdh-es: key agreement: %v", err)
+	}
+
+	kek := concatKDF(shared, ecdhESA256KWAlg, 32)
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+	return aesKeyUnwrap(block, wrapped)
+}
+
+// concatKDF is a single-round NIST SP 800-56A concatenation KDF:
+// SHA-256(counter || Z || len(algID) || algID), truncated to keyLenBytes.
+// Its fixed info holds only the length-prefixed algID; the PartyUInfo,
+// PartyVInfo and key-length fields of JWE's ECDH-ES fixed info are not
+// included.
+func concatKDF(z []byte, algID string, keyLenBytes int) []byte {
+	h := sha256.New()
+	var counter [4]byte
+	binary.BigEndian.PutUint32(counter[:], 1)
+	h.Write(counter[:])
+	h.Write(z)
+
+	var algIDLen [4]byte
+	binary.BigEndian.PutUint32(algIDLen[:], uint32(len(algID)))
+	h.Write(algIDLen[:])
+	h.Write([]byte(algID))
+
+	return h.Sum(nil)[:keyLenBytes]
+}
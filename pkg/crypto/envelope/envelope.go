@@ -0,0 +1,203 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package envelope implements JWE-style envelope encryption (RFC 7516) for
+// artifacts that shouldn't sit in plaintext in shared storage, such as
+// crash PoCs and bytecode found by the fuzzer. A fresh AES-256-GCM content
+// encryption key (CEK) is generated per artifact and wrapped once per
+// recipient, so any one of several recipients' private keys can decrypt it
+// later.
+package envelope
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// KeyWrapper wraps a content encryption key for one recipient.
+type KeyWrapper interface {
+	// Algorithm is the JWE "alg" value this wrapper implements, e.g.
+	// "RSA-OAEP" or "ECDH-ES+A256KW".
+	Algorithm() string
+	// WrapKey encrypts cek for this recipient. header carries any public,
+	// per-recipient parameters the matching KeyUnwrapper needs (e.g. an
+	// ephemeral public key for ECDH-ES).
+	WrapKey(cek []byte) (wrapped []byte, header map[string]string, err error)
+}
+
+// KeyUnwrapper recovers a content encryption key previously wrapped by the
+// matching KeyWrapper.
+type KeyUnwrapper interface {
+	Algorithm() string
+	UnwrapKey(wrapped []byte, header map[string]string) (cek []byte, err error)
+}
+
+// Recipient pairs a wrapper with the label an operator used to refer to it
+// (e.g. the path of the public key file), purely for error messages.
+type Recipient struct {
+	Label   string
+	Wrapper KeyWrapper
+}
+
+// recipientEntry is one "recipients[]" element of the serialized envelope.
+type recipientEntry struct {
+	Alg          string            `json:"alg"`
+	Header       map[string]string `json:"header,omitempty"`
+	EncryptedKey string            `json:"encrypted_key"`
+}
+
+// Envelope is the JSON (general, multi-recipient) serialization of a JWE.
+// RFC 7516's compact serialization only supports a single recipient, which
+// doesn't fit the "any of several keys can decrypt" model this package is
+// built for, so the general JSON serialization is used instead.
+type Envelope struct {
+	// Enc is the JWE "enc" value; always A256GCM here.
+	Enc string `json:"enc"`
+	// IV and Ciphertext/Tag are the AES-256-GCM output over the plaintext,
+	// with no additional authenticated data.
+	IV         string           `json:"iv"`
+	Ciphertext string           `json:"ciphertext"`
+	Tag        string           `json:"tag"`
+	Recipients []recipientEntry `json:"recipients"`
+}
+
+const contentEncAlg = "A256GCM"
+
+// Seal encrypts plaintext with a fresh random CEK and wraps that CEK for
+// every given recipient.
+func Seal(plaintext []byte, recipients ...Recipient) (*Envelope, error) {
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("envelope: at least one recipient is required")
+	}
+
+	cek := make([]byte, 32) // AES-256
+	if _, err := rand.Read(cek); err != nil {
+		return nil, fmt.Errorf("envelope: generating content key: %v", err)
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, fmt.Errorf("envelope: %v", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("envelope: %v", err)
+	}
+	iv := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(iv); err != nil {
+		return nil, fmt.Errorf("envelope: generating nonce: %v", err)
+	}
+
+	sealed := gcm.Seal(nil, iv, plaintext, nil)
+	ciphertext, tag := sealed[:len(sealed)-gcm.Overhead()], sealed[len(sealed)-gcm.Overhead():]
+
+	env := &Envelope{
+		Enc:        contentEncAlg,
+		IV:         base64.RawURLEncoding.EncodeToString(iv),
+		Ciphertext: base64.RawURLEncoding.EncodeToString(ciphertext),
+		Tag:        base64.RawURLEncoding.EncodeToString(tag),
+	}
+
+	for _, r := range recipients {
+		wrapped, header, err := r.Wrapper.WrapKey(cek)
+		if err != nil {
+			return nil, fmt.Errorf("envelope: wrapping key for recipient %s: %v", r.Label, err)
+		}
+		env.Recipients = append(env.Recipients, recipientEntry{
+			Alg:          r.Wrapper.Algorithm(),
+			Header:       header,
+			EncryptedKey: base64.RawURLEncoding.EncodeToString(wrapped),
+		})
+	}
+
+	return env, nil
+}
+
+// Open decrypts env using whichever of unwrappers matches one of the
+// envelope's recipient entries by algorithm; it tries every matching entry
+// in order and returns the first one that successfully unwraps the CEK and
+// authenticates the ciphertext.
+func Open(env *Envelope, unwrappers ...KeyUnwrapper) ([]byte, error) {
+	iv, err := base64.RawURLEncoding.DecodeString(env.IV)
+	if err != nil {
+		return nil, fmt.Errorf("envelope: decoding iv: %v", err)
+	}
+	ciphertext, err := base64.RawURLEncoding.DecodeString(env.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("envelope: decoding ciphertext: %v", err)
+	}
+	tag, err := base64.RawURLEncoding.DecodeString(env.Tag)
+	if err != nil {
+		return nil, fmt.Errorf("envelope: decoding tag: %v", err)
+	}
+
+	var lastErr error
+	for _, entry := range env.Recipients {
+		for _, unwrapper := range unwrappers {
+			if unwrapper.Algorithm() != entry.Alg {
+				continue
+			}
+			wrapped, err := base64.RawURLEncoding.DecodeString(entry.EncryptedKey)
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			cek, err := unwrapper.UnwrapKey(wrapped, entry.Header)
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			plaintext, err := decryptContent(cek, iv, append(ciphertext, tag...))
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			return plaintext, nil
+		}
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no matching recipient key")
+	}
+	return nil, fmt.Errorf("envelope: could not decrypt with any given key: %v", lastErr)
+}
+
+func decryptContent(cek, iv, sealed []byte) ([]byte, error) {
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return gcm.Open(nil, iv, sealed, nil)
+}
+
+// Marshal serializes env as JSON.
+func (env *Envelope) Marshal() ([]byte, error) {
+	return json.MarshalIndent(env, "", "  ")
+}
+
+// UnmarshalEnvelope parses an Envelope previously produced by Marshal.
+func UnmarshalEnvelope(data []byte) (*Envelope, error) {
+	env := &Envelope{}
+	if err := json.Unmarshal(data, env); err != nil {
+		return nil, err
+	}
+	return env, nil
+}
@@ -0,0 +1,166 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envelope
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// jweKeySpecPrefix is the scheme buzzer flags use to name a PEM-encoded
+// recipient key, e.g. `--encryption-keys jwe:/path/to/pub.pem`. It exists so
+// ParseRecipientSpecs/ParseUnwrapperSpecs can grow to support alternative
+// wrappers (age, PKCS#11) behind their own scheme without changing the flag
+// format.
+const jweKeySpecPrefix = "jwe:"
+
+// ParseRecipientSpecs turns the comma-separated value of --encryption-keys
+// into one Recipient per entry, loading each PEM-encoded public key and
+// picking RSA-OAEP or ECDH-ES+A256KW based on its type.
+func ParseRecipientSpecs(specs string) ([]Recipient, error) {
+	var recipients []Recipient
+	for _, spec := range strings.Split(specs, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		path, err := parseKeySpec(spec)
+		if err != nil {
+			return nil, err
+		}
+		wrapper, err := wrapperForPublicKeyFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("envelope: loading recipient %s: %v", spec, err)
+		}
+		recipients = append(recipients, Recipient{Label: spec, Wrapper: wrapper})
+	}
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("envelope: --encryption-keys did not name any keys")
+	}
+	return recipients, nil
+}
+
+// ParseUnwrapperSpecs turns the comma-separated value of --decryption-keys
+// (as used by `buzzer decrypt`) into KeyUnwrappers, loading each PEM-encoded
+// private key.
+func ParseUnwrapperSpecs(specs string) ([]KeyUnwrapper, error) {
+	var unwrappers []KeyUnwrapper
+	for _, spec := range strings.Split(specs, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		path, err := parseKeySpec(spec)
+		if err != nil {
+			return nil, err
+		}
+		unwrapper, err := unwrapperForPrivateKeyFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("envelope: loading decryption key %s: %v", spec, err)
+		}
+		unwrappers = append(unwrappers, unwrapper)
+	}
+	if len(unwrappers) == 0 {
+		return nil, fmt.Errorf("envelope: --decryption-keys did not name any keys")
+	}
+	return unwrappers, nil
+}
+
+func parseKeySpec(spec string) (path string, err error) {
+	if !strings.HasPrefix(spec, jweKeySpecPrefix) {
+		return "", fmt.Errorf("envelope: key spec %q must start with %q", spec, jweKeySpecPrefix)
+	}
+	return strings.TrimPrefix(spec, jweKeySpecPrefix), nil
+}
+
+func wrapperForPublicKeyFile(path string) (KeyWrapper, error) {
+	pub, err := parsePublicKeyPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	switch key := pub.(type) {
+	case *rsa.PublicKey:
+		return &RSAOAEPWrapper{Public: key}, nil
+	case *ecdsa.PublicKey:
+		if key.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("only P-256 is supported for ECDH-ES, got %s", key.Curve.Params().Name)
+		}
+		ecdhPub, err := key.ECDH()
+		if err != nil {
+			return nil, fmt.Errorf("converting to ECDH key: %v", err)
+		}
+		return &ECDHESP256Wrapper{Public: ecdhPub}, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", pub)
+	}
+}
+
+func unwrapperForPrivateKeyFile(path string) (KeyUnwrapper, error) {
+	priv, err := parsePrivateKeyPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	switch key := priv.(type) {
+	case *rsa.PrivateKey:
+		return &RSAOAEPWrapper{Private: key}, nil
+	case *ecdsa.PrivateKey:
+		if key.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("only P-256 is supported for ECDH-ES, got %s", key.Curve.Params().Name)
+		}
+		ecdhPriv, err := key.ECDH()
+		if err != nil {
+			return nil, fmt.Errorf("converting to ECDH key: %v", err)
+		}
+		return &ECDHESP256Wrapper{Private: ecdhPriv, Public: ecdhPriv.PublicKey()}, nil
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", priv)
+	}
+}
+
+func parsePublicKeyPEM(path string) (any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found in %s", path)
+	}
+	return x509.ParsePKIXPublicKey(block.Bytes)
+}
+
+func parsePrivateKeyPEM(path string) (any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found in %s", path)
+	}
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	return x509.ParseECPrivateKey(block.Bytes)
+}
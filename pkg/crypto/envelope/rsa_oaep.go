@@ -0,0 +1,48 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envelope
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+)
+
+// rsaOAEPAlg is the JWE "alg" this wrapper implements.
+const rsaOAEPAlg = "RSA-OAEP"
+
+// RSAOAEPWrapper wraps/unwraps a content encryption key with RSA-OAEP
+// (SHA-256), matching the JWE "RSA-OAEP" algorithm.
+type RSAOAEPWrapper struct {
+	Public  *rsa.PublicKey
+	Private *rsa.PrivateKey
+}
+
+// Algorithm implements KeyWrapper and KeyUnwrapper.
+func (w *RSAOAEPWrapper) Algorithm() string { return rsaOAEPAlg }
+
+// WrapKey implements KeyWrapper.
+func (w *RSAOAEPWrapper) WrapKey(cek []byte) ([]byte, map[string]string, error) {
+	wrapped, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, w.Public, cek, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return wrapped, nil, nil
+}
+
+// UnwrapKey implements KeyUnwrapper.
+func (w *RSAOAEPWrapper) UnwrapKey(wrapped []byte, _ map[string]string) ([]byte, error) {
+	return rsa.DecryptOAEP(sha256.New(), rand.Reader, w.Private, wrapped, nil)
+}
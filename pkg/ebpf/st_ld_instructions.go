@@ -16,6 +16,7 @@ package ebpf
 
 import (
 	"fmt"
+	"math/rand"
 )
 
 // MemoryInstruction Represents an eBPF load/store operation with an immediate value.
@@ -85,6 +86,8 @@ func (c *MemoryInstruction) GeneratePoc() []string {
 	}
 	if c.InstructionClass == InsClassLd && c.Mode == StLdModeIMM {
 		macro = fmt.Sprintf("BPF_LD_MAP_FD(/*dst=*/%s, map_fd)", c.DstReg.ToString())
+	} else if c.Mode == StLdModeATOMIC {
+		macro = fmt.Sprintf("BPF_ATOMIC_OP(%s, %s, /*dst=*/%s, /*src=*/%s, /*offset=*/%d)", size, atomicOpName(c.Imm), c.DstReg.ToString(), c.SrcReg.ToString(), c.Offset)
 	} else if c.InstructionClass == InsClassStx || c.InstructionClass == InsClassLdx {
 		macro = fmt.Sprintf("BPF_MEM_OPERATION(%s, %s, /*dst=*/%d, /*src=*/%d, /*offset=*/%d)", insClass, size, c.DstReg.ToString(), c.SrcReg.ToString(), c.Offset)
 	} else {
@@ -246,3 +249,104 @@ func MemXor64(dst, src *Register, offset int16) Instruction {
 func MemXor(dst, src *Register, offset int16) Instruction {
 	return newAtomicInstruction(dst, src, StLdSizeW, InsClassStx, offset, int32(AluXor))
 }
+
+// atomicFetch is the BPF_FETCH modifier bit (0x01): OR it into a fetch-less
+// ALU atomic op's imm to additionally load the pre-modification value into
+// the src register.
+const atomicFetch = int32(0x01)
+
+// atomicXchg and atomicCmpXchg are not ALU ops with BPF_FETCH OR'd in; the
+// kernel gives them their own fixed imm encodings (0xe1 and 0xf1).
+const (
+	atomicXchg    = int32(0xe0) | atomicFetch
+	atomicCmpXchg = int32(0xf0) | atomicFetch
+)
+
+// atomicOpName returns the BPF_ATOMIC_OP operand name for imm, as produced
+// by newAtomicInstruction, including a trailing "| BPF_FETCH" for the
+// fetching ALU variants.
+func atomicOpName(imm int32) string {
+	switch imm &^ atomicFetch {
+	case int32(AluAdd):
+		return withFetchSuffix("BPF_ADD", imm)
+	case int32(AluOr):
+		return withFetchSuffix("BPF_OR", imm)
+	case int32(AluAnd):
+		return withFetchSuffix("BPF_AND", imm)
+	case int32(AluXor):
+		return withFetchSuffix("BPF_XOR", imm)
+	}
+	switch imm {
+	case atomicXchg:
+		return "BPF_XCHG"
+	case atomicCmpXchg:
+		return "BPF_CMPXCHG"
+	default:
+		return "unknown"
+	}
+}
+
+func withFetchSuffix(op string, imm int32) string {
+	if imm&atomicFetch != 0 {
+		return op + " | BPF_FETCH"
+	}
+	return op
+}
+
+// MemFetchAdd64 Atomically adds `src` into the 8 byte value at `dst` and
+// stores the value `dst` had before the add back into `src`.
+func MemFetchAdd64(dst, src *Register, offset int16) Instruction {
+	return newAtomicInstruction(dst, src, StLdSizeDW, InsClassStx, offset, int32(AluAdd)|atomicFetch)
+}
+
+// MemFetchAdd Atomically adds `src` into the 4 byte value at `dst` and
+// stores the value `dst` had before the add back into `src`.
+func MemFetchAdd(dst, src *Register, offset int16) Instruction {
+	return newAtomicInstruction(dst, src, StLdSizeW, InsClassStx, offset, int32(AluAdd)|atomicFetch)
+}
+
+// MemXchg64 Atomically exchanges the 8 byte value at `dst` with `src`.
+func MemXchg64(dst, src *Register, offset int16) Instruction {
+	return newAtomicInstruction(dst, src, StLdSizeDW, InsClassStx, offset, atomicXchg)
+}
+
+// MemXchg Atomically exchanges the 4 byte value at `dst` with `src`.
+func MemXchg(dst, src *Register, offset int16) Instruction {
+	return newAtomicInstruction(dst, src, StLdSizeW, InsClassStx, offset, atomicXchg)
+}
+
+// MemCmpXchg64 Atomically compares the 8 byte value at `dst` against R0,
+// and if equal swaps it for `src`, following the kernel's BPF_CMPXCHG
+// convention of using R0 as the implicit comparison operand.
+func MemCmpXchg64(dst, src *Register, offset int16) Instruction {
+	return newAtomicInstruction(dst, src, StLdSizeDW, InsClassStx, offset, atomicCmpXchg)
+}
+
+// MemCmpXchg Atomically compares the 4 byte value at `dst` against R0, and
+// if equal swaps it for `src`, following the kernel's BPF_CMPXCHG
+// convention of using R0 as the implicit comparison operand.
+func MemCmpXchg(dst, src *Register, offset int16) Instruction {
+	return newAtomicInstruction(dst, src, StLdSizeW, InsClassStx, offset, atomicCmpXchg)
+}
+
+// AtomicMemOps lists every atomic memory instruction constructor a strategy
+// generator can mix into the plain stores/loads it otherwise emits, so the
+// fuzzer also drives the verifier's atomic-op paths. Shared here instead of
+// duplicated per strategy package.
+var AtomicMemOps = []func(dst, src *Register, offset int16) Instruction{
+	MemFetchAdd, MemFetchAdd64,
+	MemXchg, MemXchg64,
+	MemCmpXchg, MemCmpXchg64,
+}
+
+// RandomAtomicOrStore returns a plain 8-byte store to dst+offset most of the
+// time, occasionally substituting a random pick from AtomicMemOps instead
+// (1-in-chance, chance <= 0 means never). It's meant to be called once per
+// generated instruction slot by strategies that want to occasionally
+// exercise the atomic-op paths without hand-rolling the substitution.
+func RandomAtomicOrStore(dst, src *Register, offset int16, chance int) Instruction {
+	if chance > 0 && rand.Intn(chance) == 0 {
+		return AtomicMemOps[rand.Intn(len(AtomicMemOps))](dst, src, offset)
+	}
+	return StDW(dst, src, offset)
+}
@@ -0,0 +1,161 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package playground
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"buzzer/pkg/corpus"
+	"buzzer/pkg/strategies"
+)
+
+// StrategyName is the value --fuzz-strategy takes to select this strategy.
+const StrategyName = "playground"
+
+// seedReplayChance is how often, out of every round, Fuzz replays a
+// previously-interesting program from the corpus instead of generating a
+// fresh one, so a restarted run keeps exploring around what already worked.
+const seedReplayChance = 10
+
+// crashDetector is implemented by executors that can tell whether the last
+// Load call crashed the kernel, as opposed to just being rejected by the
+// verifier (which is the common case and not itself a crash). It's checked
+// with a type assertion for the same reason as strategies.VerifierLoader.
+type crashDetector interface {
+	Crashed() bool
+}
+
+// kernelVersioner is implemented by executors that know which kernel they
+// ran the program against, so Fuzz can record it in the corpus manifest.
+type kernelVersioner interface {
+	KernelVersion() string
+}
+
+// Strategy just runs the Generator and feeds whatever it builds to the
+// executor; it exists to exercise the basic fuzzing loop end to end.
+type Strategy struct {
+	metrics strategies.MetricsRecorder
+	corpus  *corpus.Corpus
+	seeds   []*corpus.Manifest
+}
+
+// SetMetricsRecorder lets ControlUnit attach a MetricsRecorder so this
+// strategy's generation and verifier timings show up in MetricsCollection.
+func (s *Strategy) SetMetricsRecorder(m strategies.MetricsRecorder) {
+	s.metrics = m
+}
+
+// SetCorpus lets ControlUnit attach a corpus so any program that increases
+// coverage or crashes gets persisted for later reseeding/reproduction.
+func (s *Strategy) SetCorpus(c *corpus.Corpus) {
+	s.corpus = c
+}
+
+// Seed lets ControlUnit hand this strategy the manifests Reseed loaded from
+// the corpus; Fuzz occasionally replays one of them instead of generating a
+// fresh program.
+func (s *Strategy) Seed(manifests []*corpus.Manifest) {
+	s.seeds = manifests
+}
+
+// Fuzz builds one program (a replayed seed about one round in
+// seedReplayChance when it has any, a freshly generated one otherwise) and,
+// when the executor supports it, loads
+// it through the verifier, recording how long each step took and persisting
+// the program to the corpus if it crashed or increased coverage.
+func (s *Strategy) Fuzz(ctx context.Context, e strategies.ExecutorInterface, cm strategies.CoverageManager) error {
+	bytecode, poc := s.nextProgram()
+
+	loader, ok := e.(strategies.VerifierLoader)
+	if !ok {
+		return nil
+	}
+
+	var kernelVersion string
+	if kv, ok := e.(kernelVersioner); ok {
+		kernelVersion = kv.KernelVersion()
+	}
+
+	coverageBefore := countCoverageLines(cm)
+	verifyStart := time.Now()
+	_, err := loader.Load(bytecode)
+	if s.metrics != nil {
+		s.metrics.RecordVerifierLatency(time.Since(verifyStart).Seconds())
+	}
+
+	if crasher, ok := e.(crashDetector); ok && crasher.Crashed() {
+		if s.corpus != nil {
+			if _, serr := s.corpus.Save(ctx, StrategyName, bytecode, poc, 0, corpus.VerdictCrash, kernelVersion); serr != nil {
+				return serr
+			}
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	if delta := countCoverageLines(cm) - coverageBefore; delta > 0 && s.corpus != nil {
+		if _, err := s.corpus.Save(ctx, StrategyName, bytecode, poc, delta, corpus.VerdictInteresting, kernelVersion); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nextProgram returns the bytecode and PoC for this round: a replayed seed
+// when pickSeed returns one, a freshly generated program otherwise.
+func (s *Strategy) nextProgram() ([]uint64, []string) {
+	if seed := s.pickSeed(); seed != nil {
+		return seed.Bytecode, seed.Poc
+	}
+
+	genStart := time.Now()
+	g := &Generator{}
+	instructions := g.Generate()
+	if s.metrics != nil {
+		s.metrics.RecordGenerationLatency(time.Since(genStart).Seconds())
+	}
+
+	var bytecode []uint64
+	var poc []string
+	for _, insn := range instructions {
+		bytecode = append(bytecode, insn.GenerateBytecode()...)
+		poc = append(poc, insn.GeneratePoc()...)
+	}
+	return bytecode, poc
+}
+
+// pickSeed returns a random seed handed to Seed, or nil most of the time so
+// Fuzz keeps generating fresh programs too.
+func (s *Strategy) pickSeed() *corpus.Manifest {
+	if len(s.seeds) == 0 || rand.Intn(seedReplayChance) != 0 {
+		return nil
+	}
+	return s.seeds[rand.Intn(len(s.seeds))]
+}
+
+// countCoverageLines sums how many lines are covered across every file cm
+// knows about, as a cheap proxy for "did this program discover anything
+// new" that Fuzz can diff across a round.
+func countCoverageLines(cm strategies.CoverageManager) int {
+	total := 0
+	for _, lines := range *cm.GetCoverageInfoMap() {
+		total += len(lines)
+	}
+	return total
+}
@@ -0,0 +1,66 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stackcorruption
+
+import (
+	"context"
+
+	"buzzer/pkg/ebpf"
+	"buzzer/pkg/strategies"
+)
+
+// StrategyName is the value --fuzz-strategy takes to select this strategy.
+const StrategyName = "stack_corruption"
+
+// stackInstructionCount is how many stack-touching instructions Strategy
+// generates per round.
+const stackInstructionCount = 30
+
+// atomicOpChance is how often, out of every generated instruction, Strategy
+// substitutes one of ebpf.AtomicMemOps instead of its usual stack store.
+const atomicOpChance = 4
+
+// Strategy stores RegR1 into successive 8-byte stack slots below RegR10
+// (offsets -8 down to -8*stackInstructionCount), occasionally substituting
+// one of ebpf.AtomicMemOps in. It emits no stack loads.
+type Strategy struct{}
+
+// Fuzz generates one program and, when the executor supports it, loads it
+// through the verifier.
+func (s *Strategy) Fuzz(ctx context.Context, e strategies.ExecutorInterface, cm strategies.CoverageManager) error {
+	instructions := s.generate()
+
+	loader, ok := e.(strategies.VerifierLoader)
+	if !ok {
+		return nil
+	}
+
+	var bytecode []uint64
+	for _, insn := range instructions {
+		bytecode = append(bytecode, insn.GenerateBytecode()...)
+	}
+	_, err := loader.Load(bytecode)
+	return err
+}
+
+func (s *Strategy) generate() []ebpf.Instruction {
+	instructions := make([]ebpf.Instruction, 0, stackInstructionCount+2)
+	for i := 0; i < stackInstructionCount; i++ {
+		off := int16(-8 * (i%64 + 1))
+		instructions = append(instructions, ebpf.RandomAtomicOrStore(ebpf.RegR10, ebpf.RegR1, off, atomicOpChance))
+	}
+	instructions = append(instructions, ebpf.Mov64(ebpf.RegR0, 0), ebpf.Exit())
+	return instructions
+}
@@ -0,0 +1,133 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package units
+
+import (
+	"math"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+)
+
+const (
+	cgroupV2CPUMax    = "/sys/fs/cgroup/cpu.max"
+	cgroupV2MemoryMax = "/sys/fs/cgroup/memory.max"
+	cgroupV1CPUQuota  = "/sys/fs/cgroup/cpu/cpu.cfs_quota_us"
+	cgroupV1CPUPeriod = "/sys/fs/cgroup/cpu/cpu.cfs_period_us"
+	cgroupV1MemLimit  = "/sys/fs/cgroup/memory/memory.limit_in_bytes"
+
+	// autoTuneEnvVar disables cgroup auto-tuning when set to "off", for
+	// users who'd rather size the pool themselves with --workers.
+	autoTuneEnvVar = "BUZZER_AUTOTUNE"
+
+	// memLimitSentinel is what cgroup v1/v2 report for "no limit set";
+	// treat it the same as not having a memory limit at all.
+	memLimitSentinel = math.MaxInt64
+)
+
+// autoTuneResult records what the tuner decided, so it can be surfaced via
+// MetricsCollection for operators to verify what was applied.
+type autoTuneResult struct {
+	Workers     int
+	MemoryLimit int64 // bytes, 0 means "no limit was applied"
+	FromCgroup  bool
+}
+
+// autoTuneEnabled reports whether cgroup-aware tuning should run at all.
+func autoTuneEnabled() bool {
+	return strings.ToLower(os.Getenv(autoTuneEnvVar)) != "off"
+}
+
+// autoTuneWorkerCount decides how many workers RunFuzzer should use when the
+// caller didn't pass an explicit --workers value. It prefers the cgroup CPU
+// quota (so containers don't oversize the pool off of host CPU counts),
+// falling back to GOMAXPROCS/NumCPU outside of a cgroup or when tuning is
+// disabled via BUZZER_AUTOTUNE=off.
+func autoTuneWorkerCount() autoTuneResult {
+	if !autoTuneEnabled() {
+		return autoTuneResult{Workers: runtime.GOMAXPROCS(0)}
+	}
+
+	memLimit := detectMemoryLimit()
+	if memLimit > 0 && memLimit < memLimitSentinel {
+		debug.SetMemoryLimit(memLimit)
+	} else {
+		memLimit = 0
+	}
+
+	if quota, period, ok := detectCPUQuota(); ok {
+		workers := int(math.Ceil(quota / period))
+		if workers < 1 {
+			workers = 1
+		}
+		return autoTuneResult{Workers: workers, MemoryLimit: memLimit, FromCgroup: true}
+	}
+
+	return autoTuneResult{Workers: runtime.GOMAXPROCS(0), MemoryLimit: memLimit}
+}
+
+// detectCPUQuota returns the cgroup CPU quota and period in microseconds,
+// checking cgroup v2 first and falling back to v1. ok is false when no
+// cgroup CPU limit is configured (e.g. "max" quota, or no cgroup at all).
+func detectCPUQuota() (quota, period float64, ok bool) {
+	if data, err := os.ReadFile(cgroupV2CPUMax); err == nil {
+		fields := strings.Fields(string(data))
+		if len(fields) == 2 && fields[0] != "max" {
+			q, errQ := strconv.ParseFloat(fields[0], 64)
+			p, errP := strconv.ParseFloat(fields[1], 64)
+			if errQ == nil && errP == nil && p > 0 {
+				return q, p, true
+			}
+		}
+		return 0, 0, false
+	}
+
+	quotaRaw, errQ := os.ReadFile(cgroupV1CPUQuota)
+	periodRaw, errP := os.ReadFile(cgroupV1CPUPeriod)
+	if errQ != nil || errP != nil {
+		return 0, 0, false
+	}
+	q, errQ := strconv.ParseFloat(strings.TrimSpace(string(quotaRaw)), 64)
+	p, errP := strconv.ParseFloat(strings.TrimSpace(string(periodRaw)), 64)
+	if errQ != nil || errP != nil || q <= 0 || p <= 0 {
+		return 0, 0, false
+	}
+	return q, p, true
+}
+
+// detectMemoryLimit returns the cgroup memory limit in bytes, checking
+// cgroup v2 first and falling back to v1. It returns memLimitSentinel when
+// no limit is configured.
+func detectMemoryLimit() int64 {
+	if data, err := os.ReadFile(cgroupV2MemoryMax); err == nil {
+		s := strings.TrimSpace(string(data))
+		if s == "max" {
+			return memLimitSentinel
+		}
+		if v, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return v
+		}
+		return memLimitSentinel
+	}
+
+	if data, err := os.ReadFile(cgroupV1MemLimit); err == nil {
+		if v, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64); err == nil {
+			return v
+		}
+	}
+	return memLimitSentinel
+}
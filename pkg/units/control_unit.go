@@ -16,8 +16,12 @@
 package units
 
 import (
+	"context"
 	"fmt"
+	"strings"
+	"sync"
 
+	"buzzer/pkg/corpus"
 	"buzzer/pkg/strategies/parse_verifier"
 	"buzzer/pkg/strategies/playground"
 	"buzzer/pkg/strategies/pointer_arithmetic"
@@ -31,48 +35,264 @@ type RunMode string
 // StrategyInterface contains all the methods that a fuzzing strategy should
 // implement.
 type StrategyInterface interface {
-	Fuzz(e strategies.ExecutorInterface, cm strategies.CoverageManager) error
+	Fuzz(ctx context.Context, e strategies.ExecutorInterface, cm strategies.CoverageManager) error
 }
 
+// Seeder is implemented by strategies that can be reseeded from previously
+// interesting programs stored in the corpus.
+type Seeder interface {
+	Seed(manifests []*corpus.Manifest)
+}
+
+// metricsRecorderSetter is implemented by strategies that can report
+// generation/verifier latencies into a strategies.MetricsRecorder.
+type metricsRecorderSetter interface {
+	SetMetricsRecorder(strategies.MetricsRecorder)
+}
+
+// corpusSetter is implemented by strategies that can persist interesting
+// programs to a corpus.Corpus.
+type corpusSetter interface {
+	SetCorpus(c *corpus.Corpus)
+}
+
+// ExecutorCloner is implemented by ExecutorInterface values that can hand
+// out independent instances safe for concurrent use from other goroutines
+// (e.g. by opening their own kernel connection). When the configured
+// executor implements it, each worker gets its own clone and CPU-bound
+// strategy work runs fully in parallel; otherwise workers fall back to
+// sharing the one executor under a mutex, which is held for each worker's
+// whole Fuzz call, so those calls run one at a time.
+type ExecutorCloner interface {
+	CloneExecutor() strategies.ExecutorInterface
+}
+
+// DefaultWorkerCount is how many workers `Init` spins up when the caller
+// doesn't set `--workers` and auto-tuning also yields no positive count.
+const DefaultWorkerCount = 1
+
 // ControlUnit directs the execution of the fuzzer.
 type ControlUnit struct {
-	strat StrategyInterface
-	ex    strategies.ExecutorInterface
-	rm    RunMode
-	cm    strategies.CoverageManager
-	rdy   bool
-}
+	strats  []StrategyInterface
+	workers int
 
-// Init prepares the control unit to be used.
-func (cu *ControlUnit) Init(executor strategies.ExecutorInterface, coverageManager strategies.CoverageManager, runMode, fuzzStrategyFlag string) error {
-	cu.ex = executor
+	// ex is the executor given to Init. When it implements ExecutorCloner,
+	// RunFuzzer hands each worker its own clone instead of using ex and
+	// exMu directly; exMu only guards ex itself as a fallback for
+	// executors that can't be cloned.
+	ex   strategies.ExecutorInterface
+	exMu sync.Mutex
+
+	rm  RunMode
+	cm  strategies.CoverageManager
+	mc  *MetricsCollection
+	rdy bool
+
+	diag *DiagAgent
+	corp *corpus.Corpus
+}
 
-	switch fuzzStrategyFlag {
+// newStrategy instantiates the StrategyInterface registered under name.
+func newStrategy(name string) (StrategyInterface, error) {
+	switch name {
 	case parseverifier.StrategyName:
-		cu.strat = &parseverifier.StrategyParseVerifierLog{}
+		return &parseverifier.StrategyParseVerifierLog{}, nil
 	case pointerarithmetic.StrategyName:
-		cu.strat = &pointerarithmetic.Strategy{
+		return &pointerarithmetic.Strategy{
 			// 60 is an arbitrary number.
 			InstructionCount: 60,
-		}
+		}, nil
 	case playground.StrategyName:
-		cu.strat = &playground.Strategy{}
+		return &playground.Strategy{}, nil
 	case stackcorruption.StrategyName:
-		cu.strat = &stackcorruption.Strategy{}
+		return &stackcorruption.Strategy{}, nil
 	default:
-		return fmt.Errorf("unknown fuzzing strategy: %s", fuzzStrategyFlag)
+		return nil, fmt.Errorf("unknown fuzzing strategy: %s", name)
+	}
+}
+
+// Init prepares the control unit to be used. fuzzStrategyFlag may name a
+// single strategy or a comma-separated list (`--fuzz-strategy a,b`); workers
+// controls how many goroutines `RunFuzzer` dispatches across that list. A
+// workers value <= 0 lets autoTuneWorkerCount pick the count, falling back to
+// DefaultWorkerCount only if that is not positive.
+func (cu *ControlUnit) Init(executor strategies.ExecutorInterface, coverageManager strategies.CoverageManager, metrics *MetricsCollection, runMode, fuzzStrategyFlag string, workers int, diagAddr string, corp *corpus.Corpus) error {
+	cu.ex = executor
+	cu.cm = coverageManager
+	cu.mc = metrics
+	cu.rm = RunMode(runMode)
+	cu.corp = corp
+
+	if cu.mc != nil {
+		cu.mc.setCoverageManager(coverageManager)
+	}
+
+	cu.strats = nil
+	for _, name := range strings.Split(fuzzStrategyFlag, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		strat, err := newStrategy(name)
+		if err != nil {
+			return err
+		}
+		if setter, ok := strat.(metricsRecorderSetter); ok && cu.mc != nil {
+			setter.SetMetricsRecorder(cu.mc)
+		}
+		if setter, ok := strat.(corpusSetter); ok && cu.corp != nil {
+			setter.SetCorpus(cu.corp)
+		}
+		cu.strats = append(cu.strats, strat)
+	}
+	if len(cu.strats) == 0 {
+		return fmt.Errorf("no fuzzing strategy given in --fuzz-strategy %q", fuzzStrategyFlag)
+	}
+
+	if workers <= 0 {
+		tuned := autoTuneWorkerCount()
+		workers = tuned.Workers
+		if cu.mc != nil {
+			cu.mc.recordAutoTune(tuned)
+		}
+	}
+	if workers <= 0 {
+		workers = DefaultWorkerCount
+	}
+	cu.workers = workers
+
+	if diagAddr != "" {
+		cu.diag = NewDiagAgent(diagAddr, cu, cu.mc)
+		if err := cu.diag.Start(); err != nil {
+			return err
+		}
+	}
+
+	if cu.corp != nil {
+		if err := cu.reseedStrategies(context.Background()); err != nil {
+			return fmt.Errorf("reseeding from corpus: %v", err)
+		}
 	}
 
 	cu.rdy = true
 	return nil
 }
 
+// reseedStrategies loads every manifest from the corpus and hands them to
+// whichever configured strategies implement Seeder.
+func (cu *ControlUnit) reseedStrategies(ctx context.Context) error {
+	manifests, err := cu.corp.Reseed(ctx)
+	if err != nil {
+		return err
+	}
+	if len(manifests) == 0 {
+		return nil
+	}
+	for _, strat := range cu.strats {
+		if seeder, ok := strat.(Seeder); ok {
+			seeder.Seed(manifests)
+		}
+	}
+	return nil
+}
+
 // IsReady indicates to the caller if the ControlUnit is initialized successully.
 func (cu *ControlUnit) IsReady() bool {
 	return cu.rdy
 }
 
 // RunFuzzer kickstars the fuzzer in the mode that was specified at Init time.
-func (cu *ControlUnit) RunFuzzer() error {
-	return cu.strat.Fuzz(cu.ex, cu.cm)
+// It starts cu.workers goroutines, each one repeatedly running one of the
+// strategies given to Init, and blocks until every worker returns or ctx is
+// cancelled, whichever happens first.
+func (cu *ControlUnit) RunFuzzer(ctx context.Context) error {
+	if cu.diag != nil {
+		defer cu.diag.Stop(context.Background())
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, cu.workers)
+
+	cloner, cloneable := cu.ex.(ExecutorCloner)
+
+	for i := 0; i < cu.workers; i++ {
+		strat := cu.strats[i%len(cu.strats)]
+
+		// Prefer giving each worker its own executor so generation and
+		// verification from different workers never wait on each other;
+		// only fall back to the shared, mutex-guarded executor when the
+		// concrete executor can't be cloned.
+		ex := cu.ex
+		lock := &cu.exMu
+		if cloneable {
+			ex = cloner.CloneExecutor()
+			lock = nil
+		}
+
+		wg.Add(1)
+		go func(strat StrategyInterface, ex strategies.ExecutorInterface, lock *sync.Mutex) {
+			defer wg.Done()
+			errs <- cu.runWorker(ctx, strat, ex, lock)
+		}(strat, ex, lock)
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil && err != context.Canceled {
+			return err
+		}
+	}
+	return nil
+}
+
+// runWorker drives strat in a loop on behalf of a single worker goroutine,
+// recording per-strategy stats after every round, until ctx is cancelled or
+// a round returns an error.
+// ex is this worker's own executor, unless lock is non-nil, in which case ex
+// is shared with every other worker holding the same lock and must only be
+// touched while holding it.
+func (cu *ControlUnit) runWorker(ctx context.Context, strat StrategyInterface, ex strategies.ExecutorInterface, lock *sync.Mutex) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		var err error
+		if lock != nil {
+			lock.Lock()
+			err = strat.Fuzz(ctx, ex, cu.cm)
+			lock.Unlock()
+		} else {
+			err = strat.Fuzz(ctx, ex, cu.cm)
+		}
+
+		if cu.mc != nil {
+			cu.mc.recordStrategyRound(strategyName(strat), err)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// strategyName returns the registered name of strat, or "unknown" for a
+// strategy type it doesn't list. Strategies are looked up by the
+// same names accepted by --fuzz-strategy.
+func strategyName(strat StrategyInterface) string {
+	switch strat.(type) {
+	case *parseverifier.StrategyParseVerifierLog:
+		return parseverifier.StrategyName
+	case *pointerarithmetic.Strategy:
+		return pointerarithmetic.StrategyName
+	case *playground.Strategy:
+		return playground.StrategyName
+	case *stackcorruption.Strategy:
+		return stackcorruption.StrategyName
+	default:
+		return "unknown"
+	}
 }
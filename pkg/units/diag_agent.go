@@ -0,0 +1,190 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package units
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync/atomic"
+	"syscall"
+)
+
+// maxSummaryBytes bounds the size of the /debug/summary response so the
+// diagnostic agent can never be turned into an unbounded memory/response
+// amplifier, even against a fuzzer with a huge coverage map.
+const maxSummaryBytes = 64 * 1024
+
+// maxProfileSeconds caps how long a caller can ask /debug/pprof/profile or
+// /debug/pprof/trace to block collecting samples, so the diagnostic agent
+// can't be used to pin a goroutine indefinitely via a large ?seconds=.
+const maxProfileSeconds = 30
+
+// DiagAgent is an opt-in, read-only introspection endpoint for a running
+// ControlUnit. It is meant to be safe to leave enabled in production: every
+// handler is read-only, bounded in size, and exposes no PII, only fuzzer
+// internals (strategy name, counters, goroutine/heap profiles).
+type DiagAgent struct {
+	addr string
+	cu   *ControlUnit
+	mc   *MetricsCollection
+	srv  *http.Server
+
+	// running is set while the HTTP server and signal handler are active,
+	// so Stop is a no-op if Start was never called.
+	running atomic.Bool
+
+	// sigCh is the SIGUSR1 channel watchSIGUSR1 ranges over. Stop calls
+	// signal.Stop on it and closes it so that goroutine doesn't leak past
+	// Stop.
+	sigCh chan os.Signal
+}
+
+// NewDiagAgent builds a DiagAgent bound to addr (host:port) that reports on
+// cu and mc. It does not start listening until Start is called.
+func NewDiagAgent(addr string, cu *ControlUnit, mc *MetricsCollection) *DiagAgent {
+	return &DiagAgent{addr: addr, cu: cu, mc: mc}
+}
+
+// Start begins serving diagnostics over HTTP and installs the SIGUSR1
+// handler. It returns once the listener is up, or returns the listen error;
+// serving happens in the background, and any later serve error is reported
+// asynchronously to stderr since Start is typically called right before the
+// blocking RunFuzzer call.
+func (d *DiagAgent) Start() error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/summary", d.handleSummary)
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/profile", d.handleProfile)
+	mux.HandleFunc("/debug/pprof/trace", d.handleTrace)
+
+	d.srv = &http.Server{Addr: d.addr, Handler: mux}
+	ln, err := net.Listen("tcp", d.addr)
+	if err != nil {
+		return fmt.Errorf("diag agent: %v", err)
+	}
+
+	d.sigCh = make(chan os.Signal, 1)
+	signal.Notify(d.sigCh, syscall.SIGUSR1)
+
+	d.running.Store(true)
+	go func() {
+		if err := d.srv.Serve(ln); err != nil && err != http.ErrServerClosed {
+			fmt.Fprintf(os.Stderr, "diag agent: serve error: %v\n", err)
+		}
+	}()
+	go d.watchSIGUSR1()
+
+	return nil
+}
+
+// Stop shuts down the HTTP server, waiting up to ctx's deadline for
+// in-flight requests to finish, and stops the SIGUSR1 watcher.
+func (d *DiagAgent) Stop(ctx context.Context) error {
+	if !d.running.CompareAndSwap(true, false) || d.srv == nil {
+		return nil
+	}
+	signal.Stop(d.sigCh)
+	close(d.sigCh)
+	return d.srv.Shutdown(ctx)
+}
+
+// watchSIGUSR1 prints a live summary to stderr every time the process
+// receives SIGUSR1, which is handy for peeking at a long-running,
+// non-interactive fuzz campaign without tearing it down. It exits once Stop
+// closes d.sigCh.
+func (d *DiagAgent) watchSIGUSR1() {
+	for range d.sigCh {
+		d.printSummary()
+	}
+}
+
+// printSummary writes the current summary to stderr, recovering from any
+// panic raised while building it. The HTTP handlers get this protection for
+// free from net/http's per-request recovery; this goroutine doesn't, so a
+// single bad summary (e.g. a not-yet-initialized dependency) must not take
+// down an otherwise-healthy fuzz campaign.
+func (d *DiagAgent) printSummary() {
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Fprintf(os.Stderr, "diag agent: summary panicked: %v\n", r)
+		}
+	}()
+	fmt.Fprint(os.Stderr, d.summary())
+}
+
+// handleProfile wraps pprof.Profile, clamping the caller-supplied ?seconds=
+// to maxProfileSeconds so a request can't pin a goroutine collecting a CPU
+// profile indefinitely.
+func (d *DiagAgent) handleProfile(w http.ResponseWriter, r *http.Request) {
+	clampSecondsParam(r, maxProfileSeconds)
+	pprof.Profile(w, r)
+}
+
+// handleTrace wraps pprof.Trace, clamping the caller-supplied ?seconds= to
+// maxProfileSeconds so a request can't pin a goroutine tracing indefinitely.
+func (d *DiagAgent) handleTrace(w http.ResponseWriter, r *http.Request) {
+	clampSecondsParam(r, maxProfileSeconds)
+	pprof.Trace(w, r)
+}
+
+// clampSecondsParam rewrites r's "seconds" query parameter down to max when
+// the caller asked for more (or gave a malformed value, or none at all),
+// leaving it alone otherwise.
+func clampSecondsParam(r *http.Request, max int) {
+	q := r.URL.Query()
+	secs, err := strconv.Atoi(q.Get("seconds"))
+	if err != nil || secs > max {
+		q.Set("seconds", strconv.Itoa(max))
+		r.URL.RawQuery = q.Encode()
+	}
+}
+
+func (d *DiagAgent) handleSummary(w http.ResponseWriter, r *http.Request) {
+	body := d.summary()
+	if len(body) > maxSummaryBytes {
+		body = body[:maxSummaryBytes]
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprint(w, body)
+}
+
+// summary renders the current strategy set, worker count, and per-strategy
+// generation counters.
+func (d *DiagAgent) summary() string {
+	s := fmt.Sprintf("buzzer diagnostic summary\nworkers: %d\nstrategies: %v\n", d.cu.workers, strategyNames(d.cu.strats))
+	if d.mc != nil {
+		verified, valid, _ := d.mc.getMetrics()
+		s += fmt.Sprintf("programs verified: %d\nvalid programs: %d\n", verified, valid)
+		for name, stats := range d.mc.getStrategyStats() {
+			s += fmt.Sprintf("strategy %s: programs_run=%d errors=%d\n", name, stats.ProgramsRun, stats.Errors)
+		}
+	}
+	return s
+}
+
+func strategyNames(strats []StrategyInterface) []string {
+	names := make([]string, 0, len(strats))
+	for _, s := range strats {
+		names = append(names, strategyName(s))
+	}
+	return names
+}
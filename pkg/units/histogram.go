@@ -0,0 +1,64 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package units
+
+import "fmt"
+
+// defaultLatencyBuckets are the upper bounds (in seconds) used for the
+// program generation and verifier round-trip histograms. They cover
+// sub-millisecond strategy generation up to multi-second verifier calls.
+var defaultLatencyBuckets = []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5, 10}
+
+// histogram is a minimal, OpenMetrics-compatible cumulative histogram. It
+// intentionally doesn't try to be a general purpose metrics library; it just
+// needs to back the two latency series this package exports.
+type histogram struct {
+	buckets []float64
+	counts  []uint64 // counts[i] is the number of observations <= buckets[i]
+	sum     float64
+	total   uint64
+}
+
+func newHistogram(buckets []float64) *histogram {
+	return &histogram{
+		buckets: buckets,
+		counts:  make([]uint64, len(buckets)),
+	}
+}
+
+// observe records a single value. Callers are expected to hold whatever lock
+// guards the histogram; MetricsCollection serializes access via metricsLock.
+func (h *histogram) observe(v float64) {
+	for i, upperBound := range h.buckets {
+		if v <= upperBound {
+			h.counts[i]++
+		}
+	}
+	h.sum += v
+	h.total++
+}
+
+// writeOpenMetrics renders the histogram as OpenMetrics text exposition
+// lines under the given metric name.
+func (h *histogram) writeOpenMetrics(name string) string {
+	var out string
+	for i, upperBound := range h.buckets {
+		out += fmt.Sprintf("%s_bucket{le=\"%g\"} %d\n", name, upperBound, h.counts[i])
+	}
+	out += fmt.Sprintf("%s_bucket{le=\"+Inf\"} %d\n", name, h.total)
+	out += fmt.Sprintf("%s_sum %g\n", name, h.sum)
+	out += fmt.Sprintf("%s_count %d\n", name, h.total)
+	return out
+}
@@ -20,6 +20,13 @@ import (
 	"sync"
 )
 
+// StrategyStats holds the counters tracked for a single fuzzing strategy
+// when it is run as part of a worker pool.
+type StrategyStats struct {
+	ProgramsRun int
+	Errors      int
+}
+
 // MetricsCollection Holds the actual metrics that have been collected so far
 // and provides a way to access them in a thread safe manner.
 type MetricsCollection struct {
@@ -29,6 +36,20 @@ type MetricsCollection struct {
 	programsVerified int
 	validPrograms    int
 	coverageManager  strategies.CoverageManager
+	strategyStats    map[string]*StrategyStats
+	autoTune         autoTuneResult
+
+	genLatency      *histogram
+	verifierLatency *histogram
+}
+
+// setCoverageManager attaches the coverage manager getMetrics reports on.
+// ControlUnit.Init calls this once it has one; until then, getMetrics treats
+// coverage as empty instead of dereferencing a nil CoverageManager.
+func (mc *MetricsCollection) setCoverageManager(cm strategies.CoverageManager) {
+	mc.metricsLock.Lock()
+	defer mc.metricsLock.Unlock()
+	mc.coverageManager = cm
 }
 
 func (mc *MetricsCollection) recordVerifiedProgram() {
@@ -37,6 +58,77 @@ func (mc *MetricsCollection) recordVerifiedProgram() {
 	mc.programsVerified++
 }
 
+// recordStrategyRound updates the per-strategy counters for name after one
+// round of that strategy's Fuzz has returned.
+func (mc *MetricsCollection) recordStrategyRound(name string, err error) {
+	mc.metricsLock.Lock()
+	defer mc.metricsLock.Unlock()
+	if mc.strategyStats == nil {
+		mc.strategyStats = make(map[string]*StrategyStats)
+	}
+	stats, ok := mc.strategyStats[name]
+	if !ok {
+		stats = &StrategyStats{}
+		mc.strategyStats[name] = stats
+	}
+	stats.ProgramsRun++
+	if err != nil {
+		stats.Errors++
+	}
+}
+
+// getStrategyStats returns a copy of the per-strategy counters collected so
+// far, keyed by strategy name.
+func (mc *MetricsCollection) getStrategyStats() map[string]StrategyStats {
+	mc.metricsLock.Lock()
+	defer mc.metricsLock.Unlock()
+	out := make(map[string]StrategyStats, len(mc.strategyStats))
+	for name, stats := range mc.strategyStats {
+		out[name] = *stats
+	}
+	return out
+}
+
+// recordAutoTune stores what the cgroup-aware tuner decided, so operators
+// can verify the effective worker count and memory limit that got applied.
+func (mc *MetricsCollection) recordAutoTune(result autoTuneResult) {
+	mc.metricsLock.Lock()
+	defer mc.metricsLock.Unlock()
+	mc.autoTune = result
+}
+
+// getAutoTune returns the values chosen by the last auto-tune run, if any.
+func (mc *MetricsCollection) getAutoTune() autoTuneResult {
+	mc.metricsLock.Lock()
+	defer mc.metricsLock.Unlock()
+	return mc.autoTune
+}
+
+// RecordGenerationLatency records how long a strategy took to build one
+// program. Strategies should call this from their Fuzz implementation
+// around the call to their Generator.
+func (mc *MetricsCollection) RecordGenerationLatency(seconds float64) {
+	mc.metricsLock.Lock()
+	defer mc.metricsLock.Unlock()
+	if mc.genLatency == nil {
+		mc.genLatency = newHistogram(defaultLatencyBuckets)
+	}
+	mc.genLatency.observe(seconds)
+}
+
+// RecordVerifierLatency records how long one verifier round-trip (loading
+// the generated bytecode and reading back its verdict) took. Strategies
+// should call this from their Fuzz implementation around the call to their
+// ExecutorInterface.
+func (mc *MetricsCollection) RecordVerifierLatency(seconds float64) {
+	mc.metricsLock.Lock()
+	defer mc.metricsLock.Unlock()
+	if mc.verifierLatency == nil {
+		mc.verifierLatency = newHistogram(defaultLatencyBuckets)
+	}
+	mc.verifierLatency.observe(seconds)
+}
+
 func (mc *MetricsCollection) recordValidProgram() {
 	mc.metricsLock.Lock()
 	defer mc.metricsLock.Unlock()
@@ -53,6 +145,9 @@ func (mc *MetricsCollection) getMetrics() (int, int, []CoverageInfo) {
 	mc.metricsLock.Lock()
 	defer mc.metricsLock.Unlock()
 	covArray := []CoverageInfo{}
+	if mc.coverageManager == nil {
+		return mc.programsVerified, mc.validPrograms, covArray
+	}
 	for filePath, cov := range *mc.coverageManager.GetCoverageInfoMap() {
 		covInfo := CoverageInfo{
 			coveredLines: []int{},
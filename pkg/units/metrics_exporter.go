@@ -0,0 +1,81 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package units
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// ServeMetrics starts an HTTP server on addr that publishes the metrics in
+// this collection in OpenMetrics text format at /metrics, so a long-running
+// fuzz campaign can be scraped by Prometheus instead of only being
+// inspectable through getMetrics in-process. It blocks until the server
+// stops, so callers typically run it in its own goroutine.
+func (mc *MetricsCollection) ServeMetrics(addr string) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", mc.handleMetrics)
+	return http.ListenAndServe(addr, mux)
+}
+
+func (mc *MetricsCollection) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/openmetrics-text; version=1.0.0; charset=utf-8")
+	fmt.Fprint(w, mc.renderOpenMetrics())
+}
+
+// renderOpenMetrics builds the full OpenMetrics text exposition for this
+// collection: verified/valid program totals, per-file coverage, per-strategy
+// counters, the worker count and memory limit chosen by auto-tuning, and the
+// generation/verifier latency histograms.
+func (mc *MetricsCollection) renderOpenMetrics() string {
+	verified, valid, coverage := mc.getMetrics()
+
+	out := "# TYPE buzzer_programs_verified_total counter\n"
+	out += fmt.Sprintf("buzzer_programs_verified_total %d\n", verified)
+	out += "# TYPE buzzer_valid_programs_total counter\n"
+	out += fmt.Sprintf("buzzer_valid_programs_total %d\n", valid)
+
+	out += "# TYPE buzzer_coverage_lines gauge\n"
+	for _, c := range coverage {
+		out += fmt.Sprintf("buzzer_coverage_lines{file=%q} %d\n", c.fileName, len(c.coveredLines))
+	}
+
+	out += "# TYPE buzzer_strategy_programs_run_total counter\n"
+	out += "# TYPE buzzer_strategy_errors_total counter\n"
+	for name, stats := range mc.getStrategyStats() {
+		out += fmt.Sprintf("buzzer_strategy_programs_run_total{strategy=%q} %d\n", name, stats.ProgramsRun)
+		out += fmt.Sprintf("buzzer_strategy_errors_total{strategy=%q} %d\n", name, stats.Errors)
+	}
+
+	autoTune := mc.getAutoTune()
+	out += "# TYPE buzzer_autotune_workers gauge\n"
+	out += fmt.Sprintf("buzzer_autotune_workers %d\n", autoTune.Workers)
+	out += "# TYPE buzzer_autotune_memory_limit_bytes gauge\n"
+	out += fmt.Sprintf("buzzer_autotune_memory_limit_bytes %d\n", autoTune.MemoryLimit)
+
+	mc.metricsLock.Lock()
+	if mc.genLatency != nil {
+		out += "# TYPE buzzer_program_generation_latency_seconds histogram\n"
+		out += mc.genLatency.writeOpenMetrics("buzzer_program_generation_latency_seconds")
+	}
+	if mc.verifierLatency != nil {
+		out += "# TYPE buzzer_verifier_latency_seconds histogram\n"
+		out += mc.verifierLatency.writeOpenMetrics("buzzer_verifier_latency_seconds")
+	}
+	mc.metricsLock.Unlock()
+
+	out += "# EOF\n"
+	return out
+}